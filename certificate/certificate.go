@@ -0,0 +1,160 @@
+// Package certificate loads the TLS certificates used to authenticate with
+// APNs from the formats Apple and its tooling commonly hand out: PKCS#12
+// (.p12), plain PEM, and password-protected PEM.
+package certificate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+var (
+	// ErrNoCertificate is returned when a PEM or PKCS#12 payload does not
+	// contain a certificate.
+	ErrNoCertificate = errors.New("certificate: no certificate found")
+	// ErrNoPrivateKey is returned when a PEM payload does not contain a
+	// private key. The PKCS#12 loaders do not return it.
+	ErrNoPrivateKey = errors.New("certificate: no private key found")
+	// ErrBadPassword is returned when the password for an encrypted PKCS#12
+	// or PEM payload is incorrect.
+	ErrBadPassword = errors.New("certificate: bad password")
+)
+
+// FromP12File loads a tls.Certificate from a PKCS#12 (.p12) file at path,
+// decrypting it with password.
+func FromP12File(path, password string) (tls.Certificate, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return FromP12Bytes(bytes, password)
+}
+
+// FromP12Bytes loads a tls.Certificate from PKCS#12 (.p12) encoded bytes,
+// decrypting it with password.
+func FromP12Bytes(bytes []byte, password string) (tls.Certificate, error) {
+	key, cert, err := pkcs12.Decode(bytes, password)
+	if err != nil {
+		if err == pkcs12.ErrIncorrectPassword {
+			return tls.Certificate{}, ErrBadPassword
+		}
+		return tls.Certificate{}, err
+	}
+
+	if cert == nil {
+		return tls.Certificate{}, ErrNoCertificate
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{cert.Raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}, nil
+}
+
+// FromPemFile loads a tls.Certificate from an unencrypted PEM file at path
+// containing both the certificate and private key.
+func FromPemFile(path string) (tls.Certificate, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return FromPemBytes(bytes)
+}
+
+// FromPemBytes loads a tls.Certificate from unencrypted PEM-encoded bytes
+// containing both the certificate and private key.
+func FromPemBytes(bytes []byte) (tls.Certificate, error) {
+	return fromPemBytes(bytes, "")
+}
+
+// FromEncryptedPemFile loads a tls.Certificate from a PEM file at path whose
+// private key block is encrypted, decrypting it with password.
+func FromEncryptedPemFile(path, password string) (tls.Certificate, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return FromEncryptedPemBytes(bytes, password)
+}
+
+// FromEncryptedPemBytes loads a tls.Certificate from PEM-encoded bytes whose
+// private key block is encrypted with password. Both PKCS#1 ("RSA PRIVATE
+// KEY") and PKCS#8 ("PRIVATE KEY") blocks protected by a DEK-Info header are
+// supported.
+func FromEncryptedPemBytes(bytes []byte, password string) (tls.Certificate, error) {
+	return fromPemBytes(bytes, password)
+}
+
+func fromPemBytes(bytes []byte, password string) (tls.Certificate, error) {
+	var cert tls.Certificate
+	var certBlock, keyBlock *pem.Block
+
+	for {
+		var block *pem.Block
+		block, bytes = pem.Decode(bytes)
+		if block == nil {
+			break
+		}
+
+		switch {
+		case block.Type == "CERTIFICATE":
+			if certBlock == nil {
+				certBlock = block
+			}
+			cert.Certificate = append(cert.Certificate, block.Bytes)
+		case len(block.Headers) > 0 || block.Type == "PRIVATE KEY" || block.Type == "RSA PRIVATE KEY" || block.Type == "EC PRIVATE KEY":
+			keyBlock = block
+		}
+	}
+
+	if certBlock == nil {
+		return tls.Certificate{}, ErrNoCertificate
+	}
+	if keyBlock == nil {
+		return tls.Certificate{}, ErrNoPrivateKey
+	}
+
+	leaf, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert.Leaf = leaf
+
+	keyBytes := keyBlock.Bytes
+	if x509.IsEncryptedPEMBlock(keyBlock) {
+		decrypted, err := x509.DecryptPEMBlock(keyBlock, []byte(password))
+		if err != nil {
+			return tls.Certificate{}, ErrBadPassword
+		}
+		keyBytes = decrypted
+	}
+
+	key, err := parsePrivateKey(keyBytes)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert.PrivateKey = key
+
+	return cert, nil
+}
+
+func parsePrivateKey(der []byte) (interface{}, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return x509.ParseECPrivateKey(der)
+}
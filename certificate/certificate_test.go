@@ -0,0 +1,172 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func mustSelfSignedDER(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	return der
+}
+
+func certPEM(t *testing.T) []byte {
+	t.Helper()
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: mustSelfSignedDER(t)})
+}
+
+func ecKeyPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func rsaKeyPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der := x509.MarshalPKCS1PrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der})
+}
+
+func pkcs8KeyPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func encryptedRSAKeyPEM(t *testing.T, password string) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der := x509.MarshalPKCS1PrivateKey(key)
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", der, []byte(password), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatalf("EncryptPEMBlock: %v", err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func TestFromPemBytesRSA(t *testing.T) {
+	combined := append(append([]byte{}, certPEM(t)...), rsaKeyPEM(t)...)
+
+	cert, err := FromPemBytes(combined)
+	if err != nil {
+		t.Fatalf("FromPemBytes: %v", err)
+	}
+	if cert.Leaf == nil {
+		t.Fatal("Leaf is nil")
+	}
+	if _, ok := cert.PrivateKey.(*rsa.PrivateKey); !ok {
+		t.Fatalf("PrivateKey is %T, want *rsa.PrivateKey", cert.PrivateKey)
+	}
+}
+
+func TestFromPemBytesEC(t *testing.T) {
+	combined := append(append([]byte{}, certPEM(t)...), ecKeyPEM(t)...)
+
+	cert, err := FromPemBytes(combined)
+	if err != nil {
+		t.Fatalf("FromPemBytes: %v", err)
+	}
+	if _, ok := cert.PrivateKey.(*ecdsa.PrivateKey); !ok {
+		t.Fatalf("PrivateKey is %T, want *ecdsa.PrivateKey", cert.PrivateKey)
+	}
+}
+
+func TestFromPemBytesPKCS8(t *testing.T) {
+	combined := append(append([]byte{}, certPEM(t)...), pkcs8KeyPEM(t)...)
+
+	cert, err := FromPemBytes(combined)
+	if err != nil {
+		t.Fatalf("FromPemBytes: %v", err)
+	}
+	if _, ok := cert.PrivateKey.(*ecdsa.PrivateKey); !ok {
+		t.Fatalf("PrivateKey is %T, want *ecdsa.PrivateKey", cert.PrivateKey)
+	}
+}
+
+func TestFromPemBytesMissingCertificate(t *testing.T) {
+	_, err := FromPemBytes(rsaKeyPEM(t))
+	if !errors.Is(err, ErrNoCertificate) {
+		t.Fatalf("err = %v, want ErrNoCertificate", err)
+	}
+}
+
+func TestFromPemBytesMissingPrivateKey(t *testing.T) {
+	_, err := FromPemBytes(certPEM(t))
+	if !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("err = %v, want ErrNoPrivateKey", err)
+	}
+}
+
+func TestFromEncryptedPemBytes(t *testing.T) {
+	combined := append(append([]byte{}, certPEM(t)...), encryptedRSAKeyPEM(t, "hunter2")...)
+
+	cert, err := FromEncryptedPemBytes(combined, "hunter2")
+	if err != nil {
+		t.Fatalf("FromEncryptedPemBytes: %v", err)
+	}
+	if _, ok := cert.PrivateKey.(*rsa.PrivateKey); !ok {
+		t.Fatalf("PrivateKey is %T, want *rsa.PrivateKey", cert.PrivateKey)
+	}
+}
+
+func TestFromEncryptedPemBytesBadPassword(t *testing.T) {
+	combined := append(append([]byte{}, certPEM(t)...), encryptedRSAKeyPEM(t, "hunter2")...)
+
+	_, err := FromEncryptedPemBytes(combined, "wrong")
+	if !errors.Is(err, ErrBadPassword) {
+		t.Fatalf("err = %v, want ErrBadPassword", err)
+	}
+}
+
+func TestFromP12BytesMalformedIsNotBadPassword(t *testing.T) {
+	// Garbage bytes: pkcs12.Decode will fail to parse and should surface
+	// the raw error rather than ErrBadPassword, since only
+	// pkcs12.ErrIncorrectPassword is mapped to ErrBadPassword.
+	_, err := FromP12Bytes([]byte("not a p12 file"), "whatever")
+	if err == nil {
+		t.Fatal("expected an error decoding a malformed p12 payload")
+	}
+	if bytes.Equal([]byte(err.Error()), []byte(ErrBadPassword.Error())) {
+		t.Fatalf("err = %v, want a parse error, not ErrBadPassword", err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"crypto/rand"
 
+	"strconv"
 	"sync"
 
 	"github.com/gngeorgiev/apns2/token"
@@ -141,8 +142,8 @@ func NewClient(certificate tls.Certificate) *Client {
 	return client
 }
 
-//EnablePinging starts pinging the last opened connection. This way, there's always one connection
-//kept alive which allows for quick send of push notifications
+// EnablePinging starts pinging the last opened connection. This way, there's always one connection
+// kept alive which allows for quick send of push notifications
 func (c *Client) EnablePinging(pingInterval time.Duration, pingErrorCh chan error) {
 	//lets make sure that the old goroutine has exited in case the user calls this method multiple times
 	c.DisablePinging()
@@ -190,7 +191,7 @@ func (c *Client) EnablePinging(pingInterval time.Duration, pingErrorCh chan erro
 	}()
 }
 
-//DisablePinging stops the pinging
+// DisablePinging stops the pinging
 func (c *Client) DisablePinging() {
 	c.pingingMutex.Lock()
 	defer c.pingingMutex.Unlock()
@@ -228,14 +229,14 @@ func (c *Client) Production() *Client {
 	return c
 }
 
-//IsPinging returns whether the client is currently pinging the APNS servers
+// IsPinging returns whether the client is currently pinging the APNS servers
 func (c *Client) IsPinging() bool {
 	c.pingingMutex.Lock()
 	defer c.pingingMutex.Unlock()
 	return c.pinging
 }
 
-//GetPingInterval returns the ping interval, if set on EnablePinging
+// GetPingInterval returns the ping interval, if set on EnablePinging
 func (c *Client) GetPingInterval() time.Duration {
 	return c.pingInterval
 }
@@ -264,18 +265,27 @@ func (c *Client) PushWithContext(ctx Context, n *Notification) (*Response, error
 	return c.PushWithHostContext(ctx, c.Host, n)
 }
 
-//PushWithHostContext sends a push with the specified host and context
-//useful when one client needs to send dev and prod notifications in a concurrent environment
+// PushWithHostContext sends a push with the specified host and context
+// useful when one client needs to send dev and prod notifications in a concurrent environment
 func (c *Client) PushWithHostContext(ctx Context, host string, n *Notification) (*Response, error) {
+	response, _, err := c.pushWithHostContext(ctx, host, n)
+	return response, err
+}
+
+// pushWithHostContext does the work of PushWithHostContext and additionally
+// returns the delay a 429 response's Retry-After header asked for, which
+// PushWithRetry needs but the public API has no room to return without a
+// signature break.
+func (c *Client) pushWithHostContext(ctx Context, host string, n *Notification) (*Response, time.Duration, error) {
 	payload, err := json.Marshal(n)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	url := fmt.Sprintf("%v/3/device/%v", host, n.DeviceToken)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	if c.Token != nil {
@@ -286,7 +296,7 @@ func (c *Client) PushWithHostContext(ctx Context, host string, n *Notification)
 
 	httpRes, err := c.requestWithContext(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer httpRes.Body.Close()
 
@@ -296,10 +306,17 @@ func (c *Client) PushWithHostContext(ctx Context, host string, n *Notification)
 
 	decoder := json.NewDecoder(httpRes.Body)
 	if err := decoder.Decode(&response); err != nil && err != io.EOF {
-		return &Response{}, err
+		return &Response{}, 0, err
+	}
+
+	var retryAfter time.Duration
+	if response.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(httpRes.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
 	}
 
-	return response, nil
+	return response, retryAfter, nil
 }
 
 // CloseIdleConnections closes any underlying connections which were previously
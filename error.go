@@ -0,0 +1,195 @@
+package apns2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Reason string constants as sent by APNs in the "reason" field of a
+// rejected push's response body.
+const (
+	ReasonBadCollapseID               = "BadCollapseId"
+	ReasonBadDeviceToken              = "BadDeviceToken"
+	ReasonBadExpirationDate           = "BadExpirationDate"
+	ReasonBadMessageID                = "BadMessageId"
+	ReasonBadPriority                 = "BadPriority"
+	ReasonBadTopic                    = "BadTopic"
+	ReasonDeviceTokenNotForTopic      = "DeviceTokenNotForTopic"
+	ReasonDuplicateHeaders            = "DuplicateHeaders"
+	ReasonIdleTimeout                 = "IdleTimeout"
+	ReasonMissingDeviceToken          = "MissingDeviceToken"
+	ReasonMissingTopic                = "MissingTopic"
+	ReasonPayloadEmpty                = "PayloadEmpty"
+	ReasonTopicDisallowed             = "TopicDisallowed"
+	ReasonBadCertificate              = "BadCertificate"
+	ReasonBadCertificateEnvironment   = "BadCertificateEnvironment"
+	ReasonExpiredProviderToken        = "ExpiredProviderToken"
+	ReasonForbidden                   = "Forbidden"
+	ReasonInvalidProviderToken        = "InvalidProviderToken"
+	ReasonMissingProviderToken        = "MissingProviderToken"
+	ReasonBadPath                     = "BadPath"
+	ReasonMethodNotAllowed            = "MethodNotAllowed"
+	ReasonUnregistered                = "Unregistered"
+	ReasonPayloadTooLarge             = "PayloadTooLarge"
+	ReasonTooManyProviderTokenUpdates = "TooManyProviderTokenUpdates"
+	ReasonTooManyRequests             = "TooManyRequests"
+	ReasonInternalServerError         = "InternalServerError"
+	ReasonServiceUnavailable          = "ServiceUnavailable"
+	ReasonShutdown                    = "Shutdown"
+)
+
+// Sentinel errors for every reason APNs documents, one per Reason*
+// constant above. Response.Err returns these so callers can compare with
+// errors.Is instead of matching on the Reason string themselves.
+var (
+	ErrBadCollapseID               = errors.New(ReasonBadCollapseID)
+	ErrBadDeviceToken              = errors.New(ReasonBadDeviceToken)
+	ErrBadExpirationDate           = errors.New(ReasonBadExpirationDate)
+	ErrBadMessageID                = errors.New(ReasonBadMessageID)
+	ErrBadPriority                 = errors.New(ReasonBadPriority)
+	ErrBadTopic                    = errors.New(ReasonBadTopic)
+	ErrDeviceTokenNotForTopic      = errors.New(ReasonDeviceTokenNotForTopic)
+	ErrDuplicateHeaders            = errors.New(ReasonDuplicateHeaders)
+	ErrIdleTimeout                 = errors.New(ReasonIdleTimeout)
+	ErrMissingDeviceToken          = errors.New(ReasonMissingDeviceToken)
+	ErrMissingTopic                = errors.New(ReasonMissingTopic)
+	ErrPayloadEmpty                = errors.New(ReasonPayloadEmpty)
+	ErrTopicDisallowed             = errors.New(ReasonTopicDisallowed)
+	ErrBadCertificate              = errors.New(ReasonBadCertificate)
+	ErrBadCertificateEnvironment   = errors.New(ReasonBadCertificateEnvironment)
+	ErrExpiredProviderToken        = errors.New(ReasonExpiredProviderToken)
+	ErrForbidden                   = errors.New(ReasonForbidden)
+	ErrInvalidProviderToken        = errors.New(ReasonInvalidProviderToken)
+	ErrMissingProviderToken        = errors.New(ReasonMissingProviderToken)
+	ErrBadPath                     = errors.New(ReasonBadPath)
+	ErrMethodNotAllowed            = errors.New(ReasonMethodNotAllowed)
+	ErrUnregistered                = errors.New(ReasonUnregistered)
+	ErrPayloadTooLarge             = errors.New(ReasonPayloadTooLarge)
+	ErrTooManyProviderTokenUpdates = errors.New(ReasonTooManyProviderTokenUpdates)
+	ErrTooManyRequests             = errors.New(ReasonTooManyRequests)
+	ErrInternalServerError         = errors.New(ReasonInternalServerError)
+	ErrServiceUnavailable          = errors.New(ReasonServiceUnavailable)
+	ErrShutdown                    = errors.New(ReasonShutdown)
+)
+
+var reasonErrors = map[string]error{
+	ReasonBadCollapseID:               ErrBadCollapseID,
+	ReasonBadDeviceToken:              ErrBadDeviceToken,
+	ReasonBadExpirationDate:           ErrBadExpirationDate,
+	ReasonBadMessageID:                ErrBadMessageID,
+	ReasonBadPriority:                 ErrBadPriority,
+	ReasonBadTopic:                    ErrBadTopic,
+	ReasonDeviceTokenNotForTopic:      ErrDeviceTokenNotForTopic,
+	ReasonDuplicateHeaders:            ErrDuplicateHeaders,
+	ReasonIdleTimeout:                 ErrIdleTimeout,
+	ReasonMissingDeviceToken:          ErrMissingDeviceToken,
+	ReasonMissingTopic:                ErrMissingTopic,
+	ReasonPayloadEmpty:                ErrPayloadEmpty,
+	ReasonTopicDisallowed:             ErrTopicDisallowed,
+	ReasonBadCertificate:              ErrBadCertificate,
+	ReasonBadCertificateEnvironment:   ErrBadCertificateEnvironment,
+	ReasonExpiredProviderToken:        ErrExpiredProviderToken,
+	ReasonForbidden:                   ErrForbidden,
+	ReasonInvalidProviderToken:        ErrInvalidProviderToken,
+	ReasonMissingProviderToken:        ErrMissingProviderToken,
+	ReasonBadPath:                     ErrBadPath,
+	ReasonMethodNotAllowed:            ErrMethodNotAllowed,
+	ReasonUnregistered:                ErrUnregistered,
+	ReasonPayloadTooLarge:             ErrPayloadTooLarge,
+	ReasonTooManyProviderTokenUpdates: ErrTooManyProviderTokenUpdates,
+	ReasonTooManyRequests:             ErrTooManyRequests,
+	ReasonInternalServerError:         ErrInternalServerError,
+	ReasonServiceUnavailable:          ErrServiceUnavailable,
+	ReasonShutdown:                    ErrShutdown,
+}
+
+// retryableReasons are reasons APNs documents as transient, where resending
+// the same notification unchanged can reasonably succeed. ExpiredProviderToken
+// is deliberately excluded: it means the provider token itself is stale, so
+// resending with the same token can only fail the same way again — the
+// caller needs to mint a fresh token first.
+var retryableReasons = map[string]bool{
+	ReasonIdleTimeout:         true,
+	ReasonShutdown:            true,
+	ReasonInternalServerError: true,
+	ReasonServiceUnavailable:  true,
+	ReasonTooManyRequests:     true,
+}
+
+// Err returns the sentinel error for r.Reason, usable with errors.Is, or
+// nil if r represents a successful push.
+func (r *Response) Err() error {
+	if r.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	if err, ok := reasonErrors[r.Reason]; ok {
+		return err
+	}
+
+	return errors.New(r.Reason)
+}
+
+// Retryable reports whether r.Reason is one APNs documents as transient,
+// meaning the same notification can reasonably be resent unchanged.
+func (r *Response) Retryable() bool {
+	return retryableReasons[r.Reason]
+}
+
+// ShouldInvalidateToken reports whether r.Reason indicates the device
+// token will never succeed again and should be dropped from the caller's
+// records, per Apple's guidance for Unregistered and BadDeviceToken.
+func (r *Response) ShouldInvalidateToken() bool {
+	return r.Reason == ReasonUnregistered || r.Reason == ReasonBadDeviceToken
+}
+
+// RetryPolicy controls how many times PushWithRetry resends a notification
+// that was rejected for a Retryable reason, and how long it waits between
+// attempts.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    func(attempt int) time.Duration
+}
+
+// DefaultRetryPolicy retries up to 3 times with a fixed one second delay
+// between attempts.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	Backoff: func(attempt int) time.Duration {
+		return time.Second
+	},
+}
+
+// PushWithRetry sends n like PushWithContext, and on a Retryable rejection
+// resends it according to policy, honoring a 429 response's Retry-After
+// header over policy.Backoff when APNs sends one.
+func (c *Client) PushWithRetry(ctx Context, n *Notification, policy RetryPolicy) (*Response, error) {
+	waitCtx := context.Context(ctx)
+	if waitCtx == nil {
+		waitCtx = context.Background()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, retryAfter, err := c.pushWithHostContext(ctx, c.Host, n)
+		if err != nil {
+			return resp, err
+		}
+
+		if resp.Err() == nil || !resp.Retryable() || attempt >= policy.MaxRetries {
+			return resp, nil
+		}
+
+		wait := policy.Backoff(attempt)
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-waitCtx.Done():
+			return resp, waitCtx.Err()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package apns2
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseErr(t *testing.T) {
+	ok := &Response{StatusCode: http.StatusOK}
+	if err := ok.Err(); err != nil {
+		t.Fatalf("Err() on a 200 response = %v, want nil", err)
+	}
+
+	rejected := &Response{StatusCode: http.StatusGone, Reason: ReasonUnregistered}
+	if err := rejected.Err(); !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("Err() = %v, want errors.Is match for ErrUnregistered", err)
+	}
+
+	unknown := &Response{StatusCode: http.StatusBadRequest, Reason: "SomethingNew"}
+	if err := unknown.Err(); err == nil || err.Error() != "SomethingNew" {
+		t.Fatalf("Err() for an undocumented reason = %v, want an error reading %q", err, "SomethingNew")
+	}
+}
+
+func TestResponseRetryable(t *testing.T) {
+	cases := []struct {
+		reason string
+		want   bool
+	}{
+		{ReasonIdleTimeout, true},
+		{ReasonServiceUnavailable, true},
+		{ReasonTooManyRequests, true},
+		{ReasonBadDeviceToken, false},
+		{ReasonUnregistered, false},
+	}
+
+	for _, c := range cases {
+		r := &Response{Reason: c.reason}
+		if got := r.Retryable(); got != c.want {
+			t.Errorf("Retryable() for %q = %v, want %v", c.reason, got, c.want)
+		}
+	}
+}
+
+func TestResponseShouldInvalidateToken(t *testing.T) {
+	cases := []struct {
+		reason string
+		want   bool
+	}{
+		{ReasonUnregistered, true},
+		{ReasonBadDeviceToken, true},
+		{ReasonTooManyRequests, false},
+	}
+
+	for _, c := range cases {
+		r := &Response{Reason: c.reason}
+		if got := r.ShouldInvalidateToken(); got != c.want {
+			t.Errorf("ShouldInvalidateToken() for %q = %v, want %v", c.reason, got, c.want)
+		}
+	}
+}
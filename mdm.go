@@ -0,0 +1,77 @@
+package apns2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PushTypeMDM is used for Apple Mobile Device Management check-in and
+// command-available pushes. See PushMDM.
+const PushTypeMDM PushType = "mdm"
+
+// oidUID is the ASN.1 object identifier for the userid (UID) attribute,
+// which is how MDM push certificates encode their push topic in the
+// certificate subject instead of advertising it like ordinary push certs.
+var oidUID = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}
+
+// ErrNoTopicUID is returned by APNsTopicFromCert when the certificate's
+// subject does not contain a UID attribute.
+var ErrNoTopicUID = errors.New("apns2: certificate subject has no UID")
+
+// APNsTopicFromCert extracts the APNs topic from an MDM push certificate's
+// subject UID. MDM certificates don't carry a UID OID extension the way
+// ordinary provider certificates do, so the topic has to be parsed out of
+// the subject rather than read off the certificate directly.
+func APNsTopicFromCert(cert tls.Certificate) (string, error) {
+	leaf := cert.Leaf
+	if leaf == nil {
+		if len(cert.Certificate) == 0 {
+			return "", ErrNoTopicUID
+		}
+
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return "", err
+		}
+		leaf = parsed
+	}
+
+	for _, name := range leaf.Subject.Names {
+		if name.Type.Equal(oidUID) {
+			if uid, ok := name.Value.(string); ok {
+				return uid, nil
+			}
+		}
+	}
+
+	return "", ErrNoTopicUID
+}
+
+// PushMDM sends an MDM push to pushToken, the device's APNs token, carrying
+// pushMagic, the PushMagic value the device supplied at MDM check-in. The
+// topic is derived from the provider certificate via APNsTopicFromCert, and
+// the payload is the empty {"mdm":"<PushMagic>"} body MDM pushes require.
+func (c *Client) PushMDM(pushToken, pushMagic string, expiration time.Time) (*Response, error) {
+	topic, err := APNsTopicFromCert(c.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notification{
+		DeviceToken: pushToken,
+		Topic:       topic,
+		PushType:    PushTypeMDM,
+		Expiration:  expiration,
+		// json.RawMessage marshals verbatim; a plain []byte behind the
+		// Payload interface{} would instead be base64-encoded by
+		// encoding/json, corrupting the body MDM pushes require.
+		Payload: json.RawMessage(fmt.Sprintf(`{"mdm":%q}`, pushMagic)),
+	}
+
+	return c.Push(n)
+}
@@ -0,0 +1,87 @@
+package apns2
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func mustCertWithUID(t *testing.T, uid string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			ExtraNames: []pkix.AttributeTypeAndValue{
+				{Type: oidUID, Value: uid},
+			},
+		},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestAPNsTopicFromCert(t *testing.T) {
+	cert := mustCertWithUID(t, "com.example.mdm")
+
+	topic, err := APNsTopicFromCert(cert)
+	if err != nil {
+		t.Fatalf("APNsTopicFromCert: %v", err)
+	}
+	if topic != "com.example.mdm" {
+		t.Fatalf("topic = %q, want %q", topic, "com.example.mdm")
+	}
+}
+
+func TestPushMDMSendsLiteralPayload(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = b
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cert := mustCertWithUID(t, "com.example.mdm")
+	client := &Client{
+		Host:        srv.URL,
+		Certificate: cert,
+		HTTPClient:  srv.Client(),
+	}
+
+	if _, err := client.PushMDM("devtoken", "pushmagic123", time.Time{}); err != nil {
+		t.Fatalf("PushMDM: %v", err)
+	}
+
+	// A plain []byte assigned to Notification.Payload (interface{}) would
+	// have been base64-encoded by encoding/json; json.RawMessage must be
+	// used instead so the exact {"mdm":"..."} body reaches APNs.
+	want := `{"mdm":"pushmagic123"}`
+	if string(body) != want {
+		t.Fatalf("request body = %s, want %s", body, want)
+	}
+}
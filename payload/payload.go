@@ -0,0 +1,211 @@
+// Package payload provides a fluent builder for the aps dictionary that
+// APNs notification payloads are built around, so callers don't have to
+// hand-roll the JSON themselves.
+package payload
+
+import "encoding/json"
+
+// Sound is the aps.sound dictionary used for critical alerts. Use a bare
+// string instead if the sound doesn't need a volume or the critical flag.
+type Sound struct {
+	Name     string  `json:"name,omitempty"`
+	Volume   float32 `json:"volume,omitempty"`
+	Critical int     `json:"critical,omitempty"`
+}
+
+type alert struct {
+	Title        string   `json:"title,omitempty"`
+	Subtitle     string   `json:"subtitle,omitempty"`
+	Body         string   `json:"body,omitempty"`
+	LaunchImage  string   `json:"launch-image,omitempty"`
+	LocKey       string   `json:"loc-key,omitempty"`
+	LocArgs      []string `json:"loc-args,omitempty"`
+	TitleLocKey  string   `json:"title-loc-key,omitempty"`
+	TitleLocArgs []string `json:"title-loc-args,omitempty"`
+}
+
+type aps struct {
+	Alert             *alert      `json:"alert,omitempty"`
+	Badge             *int        `json:"badge,omitempty"`
+	Sound             interface{} `json:"sound,omitempty"`
+	Category          string      `json:"category,omitempty"`
+	ThreadID          string      `json:"thread-id,omitempty"`
+	ContentAvailable  int         `json:"content-available,omitempty"`
+	MutableContent    int         `json:"mutable-content,omitempty"`
+	InterruptionLevel string      `json:"interruption-level,omitempty"`
+	RelevanceScore    *float32    `json:"relevance-score,omitempty"`
+}
+
+// Interruption levels for iOS 15+, used with Payload.InterruptionLevel.
+const (
+	InterruptionLevelPassive       = "passive"
+	InterruptionLevelActive        = "active"
+	InterruptionLevelTimeSensitive = "time-sensitive"
+	InterruptionLevelCritical      = "critical"
+)
+
+// Payload is a fluent builder over an APNs notification payload's aps
+// dictionary, plus any custom top-level fields the app wants to send
+// alongside it.
+type Payload struct {
+	aps    aps
+	custom map[string]interface{}
+}
+
+// NewPayload returns an empty Payload ready to be built up with the
+// fluent setters below.
+func NewPayload() *Payload {
+	return &Payload{}
+}
+
+// Alert sets aps.alert.body, the same as AlertBody. The alert is always
+// encoded as a dictionary, never as a plain string.
+func (p *Payload) Alert(body string) *Payload {
+	p.alertOrNew().Body = body
+	return p
+}
+
+// AlertTitle sets aps.alert.title.
+func (p *Payload) AlertTitle(title string) *Payload {
+	p.alertOrNew().Title = title
+	return p
+}
+
+// AlertSubtitle sets aps.alert.subtitle.
+func (p *Payload) AlertSubtitle(subtitle string) *Payload {
+	p.alertOrNew().Subtitle = subtitle
+	return p
+}
+
+// AlertBody sets aps.alert.body.
+func (p *Payload) AlertBody(body string) *Payload {
+	p.alertOrNew().Body = body
+	return p
+}
+
+// AlertLaunchImage sets aps.alert.launch-image, the image shown in place of
+// a launch screen when the user taps the notification.
+func (p *Payload) AlertLaunchImage(image string) *Payload {
+	p.alertOrNew().LaunchImage = image
+	return p
+}
+
+// AlertLocKey sets aps.alert.loc-key, the key of a localized string in the
+// app's Localizable.strings to use for the alert body.
+func (p *Payload) AlertLocKey(key string) *Payload {
+	p.alertOrNew().LocKey = key
+	return p
+}
+
+// AlertLocArgs sets aps.alert.loc-args, the variables to substitute into
+// the AlertLocKey string.
+func (p *Payload) AlertLocArgs(args ...string) *Payload {
+	p.alertOrNew().LocArgs = args
+	return p
+}
+
+// AlertTitleLocKey sets aps.alert.title-loc-key, the key of a localized
+// string to use for the alert title.
+func (p *Payload) AlertTitleLocKey(key string) *Payload {
+	p.alertOrNew().TitleLocKey = key
+	return p
+}
+
+// AlertTitleLocArgs sets aps.alert.title-loc-args, the variables to
+// substitute into the AlertTitleLocKey string.
+func (p *Payload) AlertTitleLocArgs(args ...string) *Payload {
+	p.alertOrNew().TitleLocArgs = args
+	return p
+}
+
+// Badge sets aps.badge, the number displayed on the app's icon.
+func (p *Payload) Badge(b int) *Payload {
+	p.aps.Badge = &b
+	return p
+}
+
+// Sound sets aps.sound to the name of a sound file in the app's bundle.
+// Use CriticalSound for critical alerts that need a volume or the
+// critical flag.
+func (p *Payload) Sound(name string) *Payload {
+	p.aps.Sound = name
+	return p
+}
+
+// CriticalSound sets aps.sound to a Sound dictionary, for critical alerts
+// that need a volume or the critical flag set.
+func (p *Payload) CriticalSound(s Sound) *Payload {
+	p.aps.Sound = s
+	return p
+}
+
+// Category sets aps.category, the identifier of the notification's
+// category as registered with UNUserNotificationCenter.
+func (p *Payload) Category(category string) *Payload {
+	p.aps.Category = category
+	return p
+}
+
+// ThreadID sets aps.thread-id, used to group related notifications.
+func (p *Payload) ThreadID(threadID string) *Payload {
+	p.aps.ThreadID = threadID
+	return p
+}
+
+// MutableContent sets aps.mutable-content, allowing a notification service
+// extension to modify the notification before it's displayed.
+func (p *Payload) MutableContent() *Payload {
+	p.aps.MutableContent = 1
+	return p
+}
+
+// ContentAvailable sets aps.content-available, for silent background
+// notifications that wake the app without displaying an alert.
+func (p *Payload) ContentAvailable() *Payload {
+	p.aps.ContentAvailable = 1
+	return p
+}
+
+// InterruptionLevel sets aps.interruption-level (iOS 15+) to one of the
+// InterruptionLevel* constants, controlling how the notification is
+// presented when the device is in Focus.
+func (p *Payload) InterruptionLevel(level string) *Payload {
+	p.aps.InterruptionLevel = level
+	return p
+}
+
+// RelevanceScore sets aps.relevance-score (iOS 15+), a value between 0 and
+// 1 that the system uses to rank notifications in a summary.
+func (p *Payload) RelevanceScore(score float32) *Payload {
+	p.aps.RelevanceScore = &score
+	return p
+}
+
+// Custom sets a top-level field alongside aps, for data the app reads out
+// of the notification's userInfo itself.
+func (p *Payload) Custom(key string, value interface{}) *Payload {
+	if p.custom == nil {
+		p.custom = make(map[string]interface{})
+	}
+	p.custom[key] = value
+	return p
+}
+
+func (p *Payload) alertOrNew() *alert {
+	if p.aps.Alert == nil {
+		p.aps.Alert = &alert{}
+	}
+	return p.aps.Alert
+}
+
+// MarshalJSON implements json.Marshaler, encoding the aps dictionary and
+// any custom fields at the top level of the notification payload.
+func (p *Payload) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.custom)+1)
+	for k, v := range p.custom {
+		m[k] = v
+	}
+	m["aps"] = p.aps
+
+	return json.Marshal(m)
+}
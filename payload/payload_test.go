@@ -0,0 +1,127 @@
+package payload
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPayloadMarshalJSONBadgeZero(t *testing.T) {
+	// Badge is a *int so that Badge(0) round-trips as "badge":0 instead of
+	// being dropped by omitempty, which would make it impossible to clear
+	// a badge.
+	p := NewPayload().Badge(0)
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{"badge":0}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestPayloadMarshalJSONEmpty(t *testing.T) {
+	got, err := json.Marshal(NewPayload())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestPayloadMarshalJSONAlert(t *testing.T) {
+	p := NewPayload().
+		AlertTitle("Title").
+		AlertBody("Body").
+		AlertLocKey("loc.key").
+		AlertLocArgs("a", "b")
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{"alert":{"title":"Title","body":"Body","loc-key":"loc.key","loc-args":["a","b"]}}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestPayloadMarshalJSONSound(t *testing.T) {
+	p := NewPayload().Sound("default.caf")
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{"sound":"default.caf"}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestPayloadMarshalJSONCriticalSound(t *testing.T) {
+	p := NewPayload().CriticalSound(Sound{Name: "alarm.caf", Volume: 1.0, Critical: 1})
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{"sound":{"name":"alarm.caf","volume":1,"critical":1}}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestPayloadMarshalJSONMutableAndContentAvailable(t *testing.T) {
+	p := NewPayload().MutableContent().ContentAvailable()
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{"content-available":1,"mutable-content":1}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestPayloadMarshalJSONCategoryThreadIDInterruptionRelevance(t *testing.T) {
+	p := NewPayload().
+		Category("MESSAGE").
+		ThreadID("thread-1").
+		InterruptionLevel(InterruptionLevelTimeSensitive).
+		RelevanceScore(0.5)
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{"category":"MESSAGE","thread-id":"thread-1","interruption-level":"time-sensitive","relevance-score":0.5}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestPayloadMarshalJSONCustom(t *testing.T) {
+	p := NewPayload().Alert("hi").Custom("url", "https://example.com/x")
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	want := `{"aps":{"alert":{"body":"hi"}},"url":"https://example.com/x"}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
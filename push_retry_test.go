@@ -0,0 +1,151 @@
+package apns2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryClient(handler http.HandlerFunc) (*Client, *httptest.Server) {
+	srv := httptest.NewServer(handler)
+	client := &Client{
+		Host:       srv.URL,
+		HTTPClient: srv.Client(),
+	}
+	return client, srv
+}
+
+func TestPushWithRetryRetriesUntilSuccess(t *testing.T) {
+	var calls int32
+	client, srv := testRetryClient(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"reason":"ServiceUnavailable"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer srv.Close()
+
+	policy := RetryPolicy{
+		MaxRetries: 5,
+		Backoff:    func(attempt int) time.Duration { return 10 * time.Millisecond },
+	}
+
+	resp, err := client.PushWithRetry(context.Background(), &Notification{DeviceToken: "abc"}, policy)
+	if err != nil {
+		t.Fatalf("PushWithRetry: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("calls = %d, want 3", got)
+	}
+}
+
+func TestPushWithRetryExhaustsMaxRetries(t *testing.T) {
+	var calls int32
+	client, srv := testRetryClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"reason":"ServiceUnavailable"}`))
+	})
+	defer srv.Close()
+
+	policy := RetryPolicy{
+		MaxRetries: 2,
+		Backoff:    func(attempt int) time.Duration { return 10 * time.Millisecond },
+	}
+
+	resp, err := client.PushWithRetry(context.Background(), &Notification{DeviceToken: "abc"}, policy)
+	if err != nil {
+		t.Fatalf("PushWithRetry: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("StatusCode = %d, want 503", resp.StatusCode)
+	}
+	// The initial attempt plus MaxRetries retries.
+	if want := int32(policy.MaxRetries + 1); atomic.LoadInt32(&calls) != want {
+		t.Fatalf("calls = %d, want %d", calls, want)
+	}
+}
+
+func TestPushWithRetryHonorsRetryAfterOverPolicyBackoff(t *testing.T) {
+	var calls int32
+	client, srv := testRetryClient(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"reason":"TooManyRequests"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer srv.Close()
+
+	policy := RetryPolicy{
+		MaxRetries: 1,
+		Backoff:    func(attempt int) time.Duration { return 100 * time.Second },
+	}
+
+	start := time.Now()
+	resp, err := client.PushWithRetry(context.Background(), &Notification{DeviceToken: "abc"}, policy)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("PushWithRetry: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+	if elapsed > 10*time.Second {
+		t.Fatalf("took %v, want close to the 1s Retry-After, not the 100s policy backoff", elapsed)
+	}
+}
+
+func TestPushWithRetryRespectsContextCancellation(t *testing.T) {
+	client, srv := testRetryClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"reason":"ServiceUnavailable"}`))
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Millisecond)
+	defer cancel()
+
+	policy := RetryPolicy{
+		MaxRetries: 5,
+		Backoff:    func(attempt int) time.Duration { return 5 * time.Second },
+	}
+
+	_, err := client.PushWithRetry(ctx, &Notification{DeviceToken: "abc"}, policy)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestPushWithRetryDoesNotRetryExpiredProviderToken(t *testing.T) {
+	var calls int32
+	client, srv := testRetryClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"reason":"ExpiredProviderToken"}`))
+	})
+	defer srv.Close()
+
+	resp, err := client.PushWithRetry(context.Background(), &Notification{DeviceToken: "abc"}, DefaultRetryPolicy)
+	if err != nil {
+		t.Fatalf("PushWithRetry: %v", err)
+	}
+	if resp.Reason != ReasonExpiredProviderToken {
+		t.Fatalf("Reason = %q, want %q", resp.Reason, ReasonExpiredProviderToken)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("calls = %d, want 1 (ExpiredProviderToken must not be retried)", got)
+	}
+}
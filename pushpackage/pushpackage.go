@@ -0,0 +1,158 @@
+// Package pushpackage builds and signs Safari Push Package and Apple Wallet
+// pass bundles. Both formats share the same on-disk shape: a zip archive
+// containing a manifest of file digests, a PKCS#7 detached signature over
+// that manifest, and the files themselves (website.json/pass.json, icons,
+// and any other resources).
+package pushpackage
+
+import (
+	"archive/zip"
+	"crypto"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// ErrNoCertificate is returned when a Writer is created with a certificate
+// that has neither a parsed leaf nor any raw certificate to parse one from,
+// so the signer's public certificate cannot be embedded in the detached
+// signature.
+var ErrNoCertificate = errors.New("pushpackage: certificate has no leaf")
+
+// ErrNoPrivateKey is returned when a Writer is created with a certificate
+// whose PrivateKey is nil or doesn't implement crypto.Signer, so it can't
+// be used to produce the detached PKCS#7 signature.
+var ErrNoPrivateKey = errors.New("pushpackage: certificate has no usable private key")
+
+// Writer accumulates the files that make up a push package and, on Close,
+// writes a manifest.json, a detached PKCS#7 signature over it, and the
+// files themselves to the underlying zip archive.
+type Writer struct {
+	zw   *zip.Writer
+	cert tls.Certificate
+	leaf *x509.Certificate
+
+	manifest map[string]string
+	closed   bool
+}
+
+// NewWriter returns a Writer that signs with cert and writes the resulting
+// push package to w. The signer's public certificate embedded in the
+// detached signature is cert.Leaf, or, when Leaf is nil, the first entry of
+// cert.Certificate parsed here. cert must also have a PrivateKey
+// implementing crypto.Signer so it can produce the detached PKCS#7
+// signature.
+func NewWriter(w io.Writer, cert tls.Certificate) (*Writer, error) {
+	if cert.Leaf == nil {
+		if len(cert.Certificate) == 0 {
+			return nil, ErrNoCertificate
+		}
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return nil, err
+		}
+		cert.Leaf = leaf
+	}
+
+	if _, ok := cert.PrivateKey.(crypto.Signer); !ok {
+		return nil, ErrNoPrivateKey
+	}
+
+	return &Writer{
+		zw:       zip.NewWriter(w),
+		cert:     cert,
+		leaf:     cert.Leaf,
+		manifest: make(map[string]string),
+	}, nil
+}
+
+// Add writes a file to the push package under name (e.g. "website.json",
+// "icon.png", "icon@2x.png") and records its SHA-1 digest for the manifest.
+func (w *Writer) Add(name string, r io.Reader) error {
+	h := sha1.New()
+	fw, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(fw, io.TeeReader(r, h)); err != nil {
+		return err
+	}
+
+	w.manifest[name] = hex.EncodeToString(h.Sum(nil))
+	return nil
+}
+
+// Close writes the manifest.json and detached signature entries and then
+// closes the underlying zip archive. The Writer must not be used after
+// Close returns.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	manifest, err := json.Marshal(w.manifest)
+	if err != nil {
+		return err
+	}
+
+	mw, err := w.zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	if _, err := mw.Write(manifest); err != nil {
+		return err
+	}
+
+	sig, err := w.sign(manifest)
+	if err != nil {
+		return err
+	}
+
+	sw, err := w.zw.Create("signature")
+	if err != nil {
+		return err
+	}
+	if _, err := sw.Write(sig); err != nil {
+		return err
+	}
+
+	return w.zw.Close()
+}
+
+// sign produces a DER-encoded detached PKCS#7 signature over manifest,
+// including the signer's certificate but omitting the content itself.
+func (w *Writer) sign(manifest []byte) ([]byte, error) {
+	sd, err := pkcs7.NewSignedData(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sd.AddSigner(w.leaf, w.cert.PrivateKey.(crypto.Signer), pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, err
+	}
+
+	sd.Detach()
+	return sd.Finish()
+}
+
+// SafariRegistration is the payload a Safari web push registration callback
+// sends to the provider when a user subscribes or unsubscribes.
+type SafariRegistration struct {
+	DeviceToken string `json:"deviceToken"`
+	PushToken   string `json:"pushToken"`
+}
+
+// EncodeSafariRegistrations encodes regs as the JSON array callers typically
+// persist from a Safari webServiceURL registration callback.
+func EncodeSafariRegistrations(regs []SafariRegistration) ([]byte, error) {
+	return json.Marshal(regs)
+}
@@ -0,0 +1,139 @@
+package pushpackage
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.mozilla.org/pkcs7"
+)
+
+func mustSelfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestNewWriterRejectsMissingCertificate(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, tls.Certificate{})
+	if !errors.Is(err, ErrNoCertificate) {
+		t.Fatalf("err = %v, want ErrNoCertificate", err)
+	}
+}
+
+func TestNewWriterRejectsMissingPrivateKey(t *testing.T) {
+	cert := mustSelfSignedCert(t)
+	cert.PrivateKey = nil
+
+	_, err := NewWriter(&bytes.Buffer{}, cert)
+	if !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("err = %v, want ErrNoPrivateKey", err)
+	}
+}
+
+func TestWriterWritesManifestAndSignature(t *testing.T) {
+	cert := mustSelfSignedCert(t)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, cert)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+
+	if err := w.Add("website.json", bytes.NewReader([]byte(`{"websiteName":"Test"}`))); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+
+	names := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		names[f.Name] = f
+	}
+
+	for _, name := range []string{"website.json", "manifest.json", "signature"} {
+		if _, ok := names[name]; !ok {
+			t.Fatalf("archive missing %q entry", name)
+		}
+	}
+
+	manifestFile, err := names["manifest.json"].Open()
+	if err != nil {
+		t.Fatalf("open manifest.json: %v", err)
+	}
+	manifest, err := ioutil.ReadAll(manifestFile)
+	if err != nil {
+		t.Fatalf("read manifest.json: %v", err)
+	}
+	manifestFile.Close()
+
+	sigFile, err := names["signature"].Open()
+	if err != nil {
+		t.Fatalf("open signature: %v", err)
+	}
+	sig, err := ioutil.ReadAll(sigFile)
+	if err != nil {
+		t.Fatalf("read signature: %v", err)
+	}
+	sigFile.Close()
+
+	p7, err := pkcs7.Parse(sig)
+	if err != nil {
+		t.Fatalf("pkcs7.Parse: %v", err)
+	}
+	p7.Content = manifest
+	if err := p7.Verify(); err != nil {
+		t.Fatalf("signature does not verify over manifest.json: %v", err)
+	}
+}
+
+func TestEncodeSafariRegistrations(t *testing.T) {
+	regs := []SafariRegistration{
+		{DeviceToken: "abc", PushToken: "def"},
+	}
+
+	got, err := EncodeSafariRegistrations(regs)
+	if err != nil {
+		t.Fatalf("EncodeSafariRegistrations: %v", err)
+	}
+
+	want := `[{"deviceToken":"abc","pushToken":"def"}]`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,273 @@
+package apns2
+
+import (
+	"math/rand"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// DefaultMaxConcurrentStreams is the SETTINGS_MAX_CONCURRENT_STREAMS Queue
+// assumes until it has observed the value the server actually advertised
+// on a live connection.
+const DefaultMaxConcurrentStreams = 1000
+
+const maxQueueRetries = 5
+
+// QueueResponse pairs a Notification with the Response or error it
+// produced, delivered on Queue.Responses.
+type QueueResponse struct {
+	Notification *Notification
+	Response     *Response
+	Error        error
+}
+
+// Queue fans notifications out across a pool of goroutines that all push
+// through the same Client. It caps the number of sends in flight at the
+// server's advertised SETTINGS_MAX_CONCURRENT_STREAMS, so that saturating
+// the limit blocks new sends rather than letting http2.Transport quietly
+// open a second TCP connection to make room.
+type Queue struct {
+	client *Client
+
+	in       chan queueItem
+	out      chan QueueResponse
+	sem      *connSemaphore
+	stopping chan struct{}
+
+	items   sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+// queueItem tracks how many times a notification has already been
+// retried, so a throttled send can be requeued instead of retried inline
+// without retrying it forever.
+type queueItem struct {
+	n       *Notification
+	attempt int
+}
+
+// NewQueue returns a Queue that sends through client using workers
+// goroutines, and starts the worker pool immediately.
+func NewQueue(client *Client, workers int) *Queue {
+	q := &Queue{
+		client:   client,
+		in:       make(chan queueItem),
+		out:      make(chan QueueResponse),
+		sem:      newConnSemaphore(func() int { return streamLimit(client) }),
+		stopping: make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		q.workers.Add(1)
+		go q.worker()
+	}
+
+	go func() {
+		q.workers.Wait()
+		close(q.out)
+	}()
+
+	return q
+}
+
+// Push enqueues n to be sent, blocking if every worker is busy. Push must
+// not be called after Close.
+func (q *Queue) Push(n *Notification) {
+	q.items.Add(1)
+	q.in <- queueItem{n: n}
+}
+
+// Close stops accepting new notifications and waits for in-flight sends
+// and any retries they've scheduled to finish before closing the channel
+// returned by Responses.
+func (q *Queue) Close() {
+	close(q.stopping)
+	q.items.Wait()
+	close(q.in)
+}
+
+// Responses returns the channel QueueResponses are delivered on, one per
+// Notification pushed.
+func (q *Queue) Responses() <-chan QueueResponse {
+	return q.out
+}
+
+func (q *Queue) worker() {
+	defer q.workers.Done()
+
+	for item := range q.in {
+		q.dispatch(item)
+	}
+}
+
+// dispatch sends item once, retrying a transport/TLS error a single time,
+// and requeues a throttled response to be retried by whichever worker is
+// next free instead of blocking this one.
+func (q *Queue) dispatch(item queueItem) {
+	defer q.items.Done()
+
+	resp, err := q.sendOnce(item.n)
+	if err == nil && isThrottled(resp) && item.attempt < maxQueueRetries && q.requeue(item) {
+		return
+	}
+
+	q.out <- QueueResponse{Notification: item.n, Response: resp, Error: err}
+}
+
+// sendOnce pushes n, tearing the connection down and retrying a single
+// time if the send itself failed (as opposed to being rejected by APNs).
+func (q *Queue) sendOnce(n *Notification) (*Response, error) {
+	q.sem.acquire()
+	resp, err := q.client.Push(n)
+	q.sem.release()
+	if err == nil {
+		return resp, nil
+	}
+
+	q.client.CloseIdleConnections()
+
+	q.sem.acquire()
+	resp, err = q.client.Push(n)
+	q.sem.release()
+	return resp, err
+}
+
+// requeue schedules item to be resent, with exponential backoff and
+// jitter applied to its attempt count, after the queue's worker goroutines
+// rather than the caller's. It reports whether the item was handed off;
+// false means the queue is closing and the caller should treat its
+// current response as final instead.
+func (q *Queue) requeue(item queueItem) bool {
+	select {
+	case <-q.stopping:
+		return false
+	default:
+	}
+
+	q.items.Add(1)
+	go func() {
+		time.Sleep(backoff(item.attempt))
+
+		// q.in stays open until this item's WaitGroup slot is released,
+		// so the send completes even while Close is waiting. Ownership of
+		// the slot passes to the dispatch call that picks this item up.
+		q.in <- queueItem{n: item.n, attempt: item.attempt + 1}
+	}()
+
+	return true
+}
+
+func isThrottled(resp *Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+func backoff(attempt int) time.Duration {
+	base := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+	return base + time.Duration(rand.Int63n(int64(base)))
+}
+
+// connSemaphore bounds how many pushes are allowed in flight at once.
+// limit is consulted fresh on every acquire, rather than fixed at
+// construction, so it reflects the server's current
+// SETTINGS_MAX_CONCURRENT_STREAMS even though that setting doesn't exist
+// until the first connection is established and can change afterwards.
+type connSemaphore struct {
+	limit func() int
+
+	mu    sync.Mutex
+	cond  *sync.Cond
+	inUse int
+}
+
+func newConnSemaphore(limit func() int) *connSemaphore {
+	s := &connSemaphore{limit: limit}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *connSemaphore) acquire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.inUse >= s.limit() {
+		s.cond.Wait()
+	}
+	s.inUse++
+}
+
+func (s *connSemaphore) release() {
+	s.mu.Lock()
+	s.inUse--
+	s.cond.Signal()
+	s.mu.Unlock()
+}
+
+// streamLimit returns the SETTINGS_MAX_CONCURRENT_STREAMS the server
+// advertised on one of client's live connections (see
+// maxConcurrentStreamsFromTransport), falling back to
+// DefaultMaxConcurrentStreams if no connection has been made yet.
+func streamLimit(client *Client) int {
+	t, ok := client.HTTPClient.Transport.(*http2.Transport)
+	if !ok {
+		return DefaultMaxConcurrentStreams
+	}
+
+	if n := maxConcurrentStreamsFromTransport(t); n > 0 {
+		return n
+	}
+
+	return DefaultMaxConcurrentStreams
+}
+
+// maxConcurrentStreamsFromTransport reaches into http2.Transport's
+// unexported connection pool to read the MAX_CONCURRENT_STREAMS setting
+// from the most recently added *http2.ClientConn of one host's connection
+// list; when connections to several hosts exist, which host's list is read
+// follows map iteration order and is not defined. The http2 package
+// doesn't expose this, so it's read via reflection: connPoolOrDef is
+// declared as the ClientConnPool interface, so its concrete value has to
+// be unwrapped with Elem() before it can be indirected into the
+// unexported *clientConnPool struct underneath. If the field is ever
+// renamed upstream this recovers and falls back to
+// DefaultMaxConcurrentStreams instead of panicking.
+func maxConcurrentStreamsFromTransport(t *http2.Transport) (n int) {
+	defer func() {
+		if recover() != nil {
+			n = 0
+		}
+	}()
+
+	pool := reflect.ValueOf(t).Elem().FieldByName("connPoolOrDef")
+	if pool.Kind() == reflect.Interface {
+		pool = pool.Elem()
+	}
+
+	poolStruct := reflect.Indirect(pool)
+	if !poolStruct.IsValid() {
+		return 0
+	}
+
+	conns := poolStruct.FieldByName("conns")
+	if !conns.IsValid() || conns.Kind() != reflect.Map || conns.Len() == 0 {
+		return 0
+	}
+
+	for _, key := range conns.MapKeys() {
+		list := conns.MapIndex(key)
+		if list.Len() == 0 {
+			continue
+		}
+
+		cc := reflect.Indirect(list.Index(list.Len() - 1))
+		limit := cc.FieldByName("maxConcurrentStreams")
+		if limit.IsValid() {
+			return int(limit.Uint())
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,154 @@
+package apns2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func TestConnSemaphoreBlocksAtLimit(t *testing.T) {
+	sem := newConnSemaphore(func() int { return 2 })
+
+	sem.acquire()
+	sem.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		sem.acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire succeeded past the limit before a release")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sem.release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire did not unblock after release")
+	}
+}
+
+func TestConnSemaphoreReReadsLimit(t *testing.T) {
+	var limit int32 = 1
+	sem := newConnSemaphore(func() int { return int(atomic.LoadInt32(&limit)) })
+
+	sem.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		sem.acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire succeeded past the limit before it was raised")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Raise the limit without ever releasing the first acquire, then
+	// nudge the waiter: it must re-read limit() rather than reuse
+	// whatever it observed when it first started waiting.
+	atomic.StoreInt32(&limit, 2)
+	sem.cond.Signal()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire did not observe the raised limit")
+	}
+}
+
+func TestMaxConcurrentStreamsFromTransportNoConnections(t *testing.T) {
+	// A freshly constructed Transport has no live connections yet, so the
+	// unexported connPoolOrDef field is still its zero value. This must
+	// not panic even though the real connection pool type is unexported.
+	n := maxConcurrentStreamsFromTransport(&http2.Transport{})
+	if n != 0 {
+		t.Fatalf("expected 0 for a transport with no connections, got %d", n)
+	}
+}
+
+func TestMaxConcurrentStreamsFromTransportLiveConnection(t *testing.T) {
+	// Drive a real HTTP/2 connection so connPoolOrDef's unexported
+	// *clientConnPool actually holds a *ClientConn, and assert the
+	// reflection path reads its negotiated MAX_CONCURRENT_STREAMS back
+	// out instead of silently degrading to the DefaultMaxConcurrentStreams
+	// fallback, which would mask the whole feature breaking if the
+	// unexported field names this relies on ever drift upstream.
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	transport := &http2.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}
+	defer transport.CloseIdleConnections()
+
+	resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+
+	n := maxConcurrentStreamsFromTransport(transport)
+	if n <= 0 {
+		t.Fatalf("maxConcurrentStreamsFromTransport returned %d after a live connection, want > 0", n)
+	}
+
+	if got := streamLimit(&Client{HTTPClient: &http.Client{Transport: transport}}); got != n {
+		t.Fatalf("streamLimit = %d, want the live value %d", got, n)
+	}
+}
+
+func TestStreamLimitFallsBackToDefaultWithoutHTTP2Transport(t *testing.T) {
+	client := &Client{HTTPClient: &http.Client{}}
+	if got := streamLimit(client); got != DefaultMaxConcurrentStreams {
+		t.Fatalf("expected DefaultMaxConcurrentStreams, got %d", got)
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	cases := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusBadRequest, false},
+	}
+
+	for _, c := range cases {
+		if got := isThrottled(&Response{StatusCode: c.status}); got != c.want {
+			t.Errorf("isThrottled(%d) = %v, want %v", c.status, got, c.want)
+		}
+	}
+}
+
+func TestBackoffGrowsWithAttempt(t *testing.T) {
+	for attempt := 0; attempt < 4; attempt++ {
+		base := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+		d := backoff(attempt)
+		if d < base || d >= 2*base {
+			t.Errorf("backoff(%d) = %v, want in [%v, %v)", attempt, d, base, 2*base)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package apns2
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Response represents the result of sending a push notification to APNs:
+// either a 200 and nothing else, or one of the 4xx/5xx rejections
+// documented in Apple's APNs Provider API reference along with the
+// Reason that explains it.
+type Response struct {
+	// StatusCode is the HTTP status code APNs returned: 200 on success,
+	// various 4xx/5xx codes on rejection (see Reason).
+	StatusCode int
+
+	// ApnsID is the apns-id response header, either an echo of the
+	// request's apns-id or one generated by APNs.
+	ApnsID string
+
+	// Reason is one of the Reason* constants in error.go, identifying
+	// why APNs rejected the notification. It is empty on success. Use
+	// Err to get it as an error usable with errors.Is.
+	Reason string
+
+	// Timestamp is the time APNs includes alongside an Unregistered
+	// rejection: the last time the device token is known to have been
+	// valid, so a provider doesn't delete tokens that were re-registered
+	// after the push was sent. It is the zero time for any other Reason.
+	Timestamp Timestamp
+}
+
+// Timestamp decodes the epoch-millisecond "timestamp" APNs returns on an
+// Unregistered rejection into a time.Time. Use Time to read it.
+type Timestamp time.Time
+
+// Time returns t as a time.Time.
+func (t Timestamp) Time() time.Time {
+	return time.Time(t)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing APNs's
+// epoch-millisecond timestamp into t.
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	var ms int64
+	if err := json.Unmarshal(b, &ms); err != nil {
+		return err
+	}
+
+	*t = Timestamp(time.UnixMilli(ms))
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding t as the same
+// epoch-millisecond form APNs uses.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).UnixMilli())
+}
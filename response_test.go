@@ -0,0 +1,42 @@
+package apns2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	want := time.UnixMilli(1700000000123).UTC()
+
+	var resp Response
+	err := json.Unmarshal([]byte(`{"reason":"Unregistered","timestamp":1700000000123}`), &resp)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if got := resp.Timestamp.Time().UTC(); !got.Equal(want) {
+		t.Fatalf("Timestamp = %v, want %v", got, want)
+	}
+	if resp.Reason != ReasonUnregistered {
+		t.Fatalf("Reason = %q, want %q", resp.Reason, ReasonUnregistered)
+	}
+}
+
+func TestTimestampMarshalJSONRoundTrip(t *testing.T) {
+	in := Timestamp(time.UnixMilli(1700000000123).UTC())
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out Timestamp
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !out.Time().Equal(in.Time()) {
+		t.Fatalf("round trip = %v, want %v", out.Time(), in.Time())
+	}
+}